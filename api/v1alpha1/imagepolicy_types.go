@@ -0,0 +1,166 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PolicyMode decides how a failed authority check affects admission.
+type PolicyMode string
+
+const (
+	// PolicyModeEnforce rejects pods whose images fail every matching authority.
+	PolicyModeEnforce PolicyMode = "enforce"
+	// PolicyModeWarn only labels the pod, it never blocks admission.
+	PolicyModeWarn PolicyMode = "warn"
+)
+
+// ImageSelector matches image references this policy applies to.
+type ImageSelector struct {
+	// Glob is a shell glob pattern (e.g. "registry.internal/*") matched
+	// against the image reference, excluding the tag/digest. Note that "*"
+	// does not cross "/": "registry.internal/*" matches
+	// "registry.internal/foo" but not "registry.internal/team/foo" -- give
+	// multi-segment repositories their own glob.
+	Glob string `json:"glob"`
+}
+
+// KeySource describes where to load a signing public key from.
+type KeySource struct {
+	// Data is a PEM-encoded public key.
+	// +optional
+	Data string `json:"data,omitempty"`
+	// SecretRef points to a namespace-local secret holding the key under
+	// a "cosign.pub" (or equivalent) data entry.
+	// +optional
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+// Identity constrains keyless verification to a specific OIDC issuer and
+// certificate subject. Both fields are treated as regular expressions.
+type Identity struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+// Rekor configures the transparency log consulted for keyless verification.
+type Rekor struct {
+	// URL overrides the default public Rekor instance.
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// Keyless configures sigstore keyless (Fulcio/Rekor) verification.
+type Keyless struct {
+	Identities []Identity `json:"identities"`
+	// +optional
+	Rekor *Rekor `json:"rekor,omitempty"`
+}
+
+// Authority is a single trust source an image may be verified against.
+// Exactly one of Key or Keyless must be set.
+type Authority struct {
+	// Key configures classic public-key verification.
+	// +optional
+	Key *KeySource `json:"key,omitempty"`
+	// Keyless configures sigstore Fulcio/Rekor keyless verification.
+	// +optional
+	Keyless *Keyless `json:"keyless,omitempty"`
+}
+
+// SignatureSource rewrites where trust data (Notary targets, cosign
+// signatures) is fetched from for images matched by this policy. The image
+// itself is always pulled from its own reference; only the lookup of its
+// signature/trust data is redirected.
+type SignatureSource struct {
+	// Registry replaces the registry host of the image reference when
+	// constructing the reference used to fetch signatures/trust data.
+	Registry string `json:"registry"`
+}
+
+// ImagePolicySpec defines which images a policy applies to and which
+// authorities (keys/identities) are trusted to sign them.
+type ImagePolicySpec struct {
+	// Images selects which image references this policy covers.
+	Images []ImageSelector `json:"images"`
+
+	// Authorities lists the trust sources an image must satisfy at least
+	// one of in order to be considered valid.
+	Authorities []Authority `json:"authorities"`
+
+	// Mode controls whether a failing image is rejected (enforce) or only
+	// flagged (warn). Defaults to enforce.
+	// +kubebuilder:validation:Enum=enforce;warn
+	// +kubebuilder:default=enforce
+	// +optional
+	Mode PolicyMode `json:"mode,omitempty"`
+
+	// SignatureSource, if set, rewrites the reference used to fetch
+	// signatures/trust data for images matched by this policy, e.g. when
+	// signatures live in a mirror or a dedicated signature registry.
+	// +optional
+	SignatureSource *SignatureSource `json:"signatureSource,omitempty"`
+
+	// SignaturePullSecrets lists Secrets, in this policy's namespace, whose
+	// docker credentials are used only when retrieving signatures/trust
+	// data, never for pulling the image itself.
+	// +optional
+	SignaturePullSecrets []corev1.LocalObjectReference `json:"signaturePullSecrets,omitempty"`
+}
+
+// ImagePolicyStatus reports the outcome of the most recent reconciliation.
+type ImagePolicyStatus struct {
+	// ObservedGeneration is the spec generation the status was computed from.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Ready is true once the policy has been validated and compiled.
+	// +optional
+	Ready bool `json:"ready,omitempty"`
+	// Reason carries a short machine-readable explanation when Ready is false.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.spec.mode`
+//+kubebuilder:printcolumn:name="Ready",type=boolean,JSONPath=`.status.ready`
+
+// ImagePolicy constrains which signing keys or identities are trusted for a
+// set of image references within its namespace.
+type ImagePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ImagePolicySpec   `json:"spec,omitempty"`
+	Status ImagePolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ImagePolicyList contains a list of ImagePolicy
+type ImagePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ImagePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ImagePolicy{}, &ImagePolicyList{})
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command tester lets an operator (or a CI pipeline) ask what Warden would
+// do to a given pod or image without deploying anything. It loads the same
+// ServiceConfig and ImagePolicy CRs the webhook does and runs each image
+// through the same validate.ImageValidatorService/PolicyStore matching
+// DefaultingWebHook.handle uses per container. It does not drive
+// validate.PodValidator: that type (and the notary/cosign client factories
+// production wiring needs) predates this tool and isn't implemented in this
+// checkout -- see main.go's own TODO. So this tester reports per-image
+// Valid/Invalid/ServiceUnavailable, not the pod-level NoAction outcome
+// PodValidator layers on top.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	cliType "github.com/docker/cli/cli/config/types"
+	"github.com/kyma-project/warden/api/v1alpha1"
+	"github.com/kyma-project/warden/internal/validate"
+	"github.com/kyma-project/warden/pkg"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+type repeatedFlag []string
+
+func (f *repeatedFlag) String() string     { return strings.Join(*f, ",") }
+func (f *repeatedFlag) Set(v string) error { *f = append(*f, v); return nil }
+
+type options struct {
+	image          string
+	podPath        string
+	namespacePath  string
+	pullSecretPath string
+	configPath     string
+	policyPaths    repeatedFlag
+}
+
+func main() {
+	if err := run(parseFlags()); err != nil {
+		fmt.Fprintln(os.Stderr, "tester: "+err.Error())
+		os.Exit(1)
+	}
+}
+
+func parseFlags() options {
+	var o options
+	flag.StringVar(&o.image, "image", "", "Validate a single image reference instead of a pod")
+	flag.StringVar(&o.podPath, "pod", "", "Path to a pod.yaml whose containers should be validated")
+	flag.StringVar(&o.namespacePath, "namespace", "", "Optional path to a namespace.yaml; defaults to the pod/flag namespace with no labels")
+	flag.StringVar(&o.pullSecretPath, "pull-secret", "", "Optional path to a dockerconfigjson pull secret used for registry auth")
+	flag.StringVar(&o.configPath, "config", "", "Path to a ServiceConfig YAML file; defaults to a zero-value ServiceConfig")
+	flag.Var(&o.policyPaths, "image-policy", "Path to an ImagePolicy YAML file; may be repeated")
+	flag.Parse()
+	return o
+}
+
+func run(o options) error {
+	if o.image == "" && o.podPath == "" {
+		return errors.New("one of --image or --pod is required")
+	}
+
+	sc, err := loadServiceConfig(o.configPath)
+	if err != nil {
+		return errors.Wrap(err, "load ServiceConfig")
+	}
+
+	policyStore := validate.NewPolicyStore()
+	for _, path := range o.policyPaths {
+		if err := loadImagePolicyInto(policyStore, path); err != nil {
+			return errors.Wrapf(err, "load ImagePolicy %s", path)
+		}
+	}
+
+	credentials, err := loadPullSecret(o.pullSecretPath)
+	if err != nil {
+		return errors.Wrap(err, "load pull secret")
+	}
+
+	namespace, err := resolveNamespace(o)
+	if err != nil {
+		return err
+	}
+
+	// No validate.RepoFactory implementation is wired into this checkout (see
+	// the package doc comment), so the notary backend's nil RepoFactory would
+	// panic the first time it tried to reach Notary. Refuse up front instead.
+	if sc.Backend != validate.BackendCosign {
+		return errors.Errorf("tester only supports backend %q; set it via the ServiceConfig YAML's \"backend\" field (--config), since no validate.RepoFactory is available to drive the notary backend in this checkout", validate.BackendCosign)
+	}
+
+	imageValidator := validate.NewImageValidator(sc, nil)
+
+	images, err := resolveImages(o)
+	if err != nil {
+		return err
+	}
+
+	return report(context.Background(), imageValidator, policyStore, namespace, images, credentials)
+}
+
+func resolveNamespace(o options) (string, error) {
+	if o.namespacePath != "" {
+		ns, err := loadNamespace(o.namespacePath)
+		if err != nil {
+			return "", errors.Wrap(err, "load namespace")
+		}
+		return ns.Name, nil
+	}
+	if o.podPath != "" {
+		pod, err := loadPod(o.podPath)
+		if err != nil {
+			return "", errors.Wrap(err, "load pod")
+		}
+		return pod.Namespace, nil
+	}
+	return "", nil
+}
+
+func resolveImages(o options) ([]string, error) {
+	if o.image != "" {
+		return []string{o.image}, nil
+	}
+
+	pod, err := loadPod(o.podPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "load pod")
+	}
+
+	images := make([]string, 0, len(pod.Spec.Containers))
+	for _, container := range pod.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	return images, nil
+}
+
+// report validates every image and prints, per container image, the
+// validation outcome, which ImagePolicy (if any) matched it, and the error
+// behind a non-Valid result. Outcomes are Valid, Invalid (checked and
+// rejected), or ServiceUnavailable (couldn't determine trust, e.g. the
+// registry was unreachable); see the package doc comment for why this
+// doesn't also report NoAction, which is a pod-level PodValidator outcome.
+func report(ctx context.Context, imageValidator validate.ImageValidatorService, policyStore *validate.PolicyStore, namespace string, images []string, credentials map[string]cliType.AuthConfig) error {
+	for _, image := range images {
+		imgCtx := ctx
+		matchedPolicy := "<none>"
+		if policy, ok := policyStore.Match(namespace, image); ok {
+			imgCtx = validate.ContextWithPolicy(imgCtx, policy)
+			matchedPolicy = policy.Name
+		}
+
+		err := imageValidator.Validate(imgCtx, image, credentials)
+		result := "Valid"
+		if err != nil {
+			// pkg.NewUnknownResultErr marks infra failures (registry/notary
+			// unreachable, can't load keys, ...) where we couldn't determine
+			// whether the image is actually trusted; don't conflate that
+			// with pkg.NewValidationFailedErr's "it was checked and rejected".
+			result = "Invalid"
+			if pkg.IsUnknownResultErr(err) {
+				result = "ServiceUnavailable"
+			}
+		}
+
+		fmt.Printf("%-60s %-10s policy=%s\n", image, result, matchedPolicy)
+		if err != nil {
+			fmt.Printf("  reason: %v\n", err)
+		}
+	}
+	return nil
+}
+
+func loadServiceConfig(path string) (*validate.ServiceConfig, error) {
+	sc := &validate.ServiceConfig{}
+	if path == "" {
+		return sc, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(raw, sc); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+func loadImagePolicyInto(store *validate.PolicyStore, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	policy := &v1alpha1.ImagePolicy{}
+	if err := yaml.Unmarshal(raw, policy); err != nil {
+		return err
+	}
+	key := types.NamespacedName{Namespace: policy.Namespace, Name: policy.Name}
+	compiled, err := validate.CompilePolicy(key, policy.Spec)
+	if err != nil {
+		return err
+	}
+
+	authorities, err := validate.ResolveAuthorities(policy.Spec.Authorities, resolveKeyOffline)
+	if err != nil {
+		return errors.Wrap(err, "resolve authorities")
+	}
+	compiled.Authorities = authorities
+
+	store.Set(key, compiled)
+	return nil
+}
+
+// resolveKeyOffline refuses every SecretRef: the tester has no cluster
+// client, so spec.authorities[].key.secretRef can't be resolved the way the
+// reconciler resolves it. Use key.data in test fixtures instead.
+func resolveKeyOffline(ref corev1.LocalObjectReference) (string, error) {
+	return "", errors.Errorf("secretRef %q cannot be resolved offline; use key.data in the test ImagePolicy instead", ref.Name)
+}
+
+func loadPod(path string) (*corev1.Pod, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pod := &corev1.Pod{}
+	if err := yaml.Unmarshal(raw, pod); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+func loadNamespace(path string) (*corev1.Namespace, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ns := &corev1.Namespace{}
+	if err := yaml.Unmarshal(raw, ns); err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+func loadPullSecret(path string) (map[string]cliType.AuthConfig, error) {
+	if path == "" {
+		return map[string]cliType.AuthConfig{}, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return validate.ParseDockerConfigJSON(raw)
+}
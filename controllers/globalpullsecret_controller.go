@@ -0,0 +1,80 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/kyma-project/warden/internal/validate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// GlobalPullSecretReconciler watches a single, operator-configured
+// corev1.Secret (--global-pull-secret=<namespace>/<name>) and keeps Target
+// up to date with its dockerconfigjson contents, so the admission webhook
+// always has a fresh cluster-wide fallback credential without restarting.
+type GlobalPullSecretReconciler struct {
+	client.Client
+	// Key is the namespace/name of the Secret this reconciler watches;
+	// every other Secret in the cluster is ignored.
+	Key    types.NamespacedName
+	Target *validate.GlobalPullSecret
+}
+
+func (r *GlobalPullSecretReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.NamespacedName != r.Key {
+		return ctrl.Result{}, nil
+	}
+
+	l := log.FromContext(ctx)
+
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, req.NamespacedName, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			l.Info("global pull secret not found, clearing cached credentials")
+			return ctrl.Result{}, r.Target.Update(nil)
+		}
+		l.Error(err, "unable to get global pull secret")
+		return ctrl.Result{}, err
+	}
+
+	if err := r.Target.Update(secret); err != nil {
+		l.Error(err, "unable to parse global pull secret")
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager, restricting its
+// watch to r.Key so unrelated Secret churn in the cluster never triggers it.
+func (r *GlobalPullSecretReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	isWatchedSecret := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return client.ObjectKeyFromObject(obj) == r.Key
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Secret{}, builder.WithPredicates(isWatchedSecret)).
+		Complete(r)
+}
@@ -18,8 +18,15 @@ package controllers
 
 import (
 	"context"
+
+	cliType "github.com/docker/cli/cli/config/types"
 	"github.com/kyma-project/warden/api/v1alpha1"
+	"github.com/kyma-project/warden/internal/validate"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -29,37 +36,126 @@ import (
 type ImagePolicyReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Store receives the compiled form of every ImagePolicy in the cluster,
+	// so the admission webhook can consult it without talking to the API
+	// server on the request path.
+	Store *validate.PolicyStore
 }
 
 //+kubebuilder:rbac:groups=validate.warden.kyma-project.io,resources=imagepolicies,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=validate.warden.kyma-project.io,resources=imagepolicies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=validate.warden.kyma-project.io,resources=imagepolicies/finalizers,verbs=update
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the ImagePolicy object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.13.0/pkg/reconcile
+// Reconcile validates the ImagePolicy's spec, compiles its image globs, and
+// publishes the result into r.Store so the admission webhook can enforce
+// per-namespace trust rules without round-tripping to the API server.
 func (r *ImagePolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	l := log.FromContext(ctx)
 
 	policy := v1alpha1.ImagePolicy{}
-	err := r.Get(ctx, req.NamespacedName, &policy)
-	if err != nil {
+	if err := r.Get(ctx, req.NamespacedName, &policy); err != nil {
+		if apierrors.IsNotFound(err) {
+			r.Store.Delete(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
 		l.Error(err, "unable to get ImagePolicy")
 		return ctrl.Result{}, err
 	}
-	//fmt.Println(policy)
+
+	compiled, err := validate.CompilePolicy(req.NamespacedName, policy.Spec)
+	if err != nil {
+		l.Error(err, "invalid ImagePolicy spec")
+		policy.Status.Ready = false
+		policy.Status.Reason = err.Error()
+		policy.Status.ObservedGeneration = policy.Generation
+		if statusErr := r.Status().Update(ctx, &policy); statusErr != nil {
+			l.Error(statusErr, "unable to update ImagePolicy status")
+		}
+		// invalid spec, nothing to retry until it changes
+		return ctrl.Result{}, nil
+	}
+
+	signatureCredentials, err := r.resolveSignaturePullSecrets(ctx, policy.Namespace, policy.Spec.SignaturePullSecrets)
+	if err != nil {
+		l.Error(err, "unable to resolve signaturePullSecrets")
+		return ctrl.Result{}, err
+	}
+	compiled.SignatureCredentials = signatureCredentials
+
+	authorities, err := validate.ResolveAuthorities(policy.Spec.Authorities, r.resolveKeySecret(ctx, policy.Namespace))
+	if err != nil {
+		l.Error(err, "unable to resolve authorities")
+		policy.Status.Ready = false
+		policy.Status.Reason = err.Error()
+		policy.Status.ObservedGeneration = policy.Generation
+		if statusErr := r.Status().Update(ctx, &policy); statusErr != nil {
+			l.Error(statusErr, "unable to update ImagePolicy status")
+		}
+		return ctrl.Result{}, err
+	}
+	compiled.Authorities = authorities
+
+	r.Store.Set(req.NamespacedName, compiled)
+
+	policy.Status.Ready = true
+	policy.Status.Reason = ""
+	policy.Status.ObservedGeneration = policy.Generation
+	if err := r.Status().Update(ctx, &policy); err != nil {
+		l.Error(err, "unable to update ImagePolicy status")
+		return ctrl.Result{}, err
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// resolveSignaturePullSecrets loads every Secret in refs (all in namespace)
+// and merges their dockerconfigjson credentials, keyed by registry, for use
+// only when retrieving signatures/trust data.
+func (r *ImagePolicyReconciler) resolveSignaturePullSecrets(ctx context.Context, namespace string, refs []corev1.LocalObjectReference) (map[string]cliType.AuthConfig, error) {
+	credentials := map[string]cliType.AuthConfig{}
+	for _, ref := range refs {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+		if err := r.Get(ctx, key, secret); err != nil {
+			return nil, err
+		}
+
+		parsed, err := validate.ParseDockerConfigJSON(secret.Data[corev1.DockerConfigJsonKey])
+		if err != nil {
+			return nil, err
+		}
+		for registry, auth := range parsed {
+			credentials[registry] = auth
+		}
+	}
+	return credentials, nil
+}
+
+// cosignPublicKeySecretKey is the Secret data entry a KeySource.SecretRef is
+// expected to hold its PEM-encoded public key under.
+const cosignPublicKeySecretKey = "cosign.pub"
+
+// resolveKeySecret returns a validate.SecretKeyResolver that loads a
+// KeySource.SecretRef's public key from namespace, for use with
+// validate.ResolveAuthorities.
+func (r *ImagePolicyReconciler) resolveKeySecret(ctx context.Context, namespace string) validate.SecretKeyResolver {
+	return func(ref corev1.LocalObjectReference) (string, error) {
+		secret := &corev1.Secret{}
+		key := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+		if err := r.Get(ctx, key, secret); err != nil {
+			return "", err
+		}
+		data, ok := secret.Data[cosignPublicKeySecretKey]
+		if !ok {
+			return "", errors.Errorf("secret %s has no %q data entry", key, cosignPublicKeySecretKey)
+		}
+		return string(data), nil
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ImagePolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.ImagePolicy{}).
 		Complete(r)
-}
\ No newline at end of file
+}
@@ -22,19 +22,23 @@ const (
 const PodType = "Pod"
 
 type DefaultingWebHook struct {
-	validationSvc validate.PodValidator
-	timeout       time.Duration
-	client        k8sclient.Client
-	decoder       *admission.Decoder
-	baseLogger    *zap.SugaredLogger
+	validationSvc    validate.PodValidator
+	policyStore      *validate.PolicyStore
+	globalPullSecret *validate.GlobalPullSecret
+	timeout          time.Duration
+	client           k8sclient.Client
+	decoder          *admission.Decoder
+	baseLogger       *zap.SugaredLogger
 }
 
-func NewDefaultingWebhook(client k8sclient.Client, ValidationSvc validate.PodValidator, timeout time.Duration, logger *zap.SugaredLogger) *DefaultingWebHook {
+func NewDefaultingWebhook(client k8sclient.Client, ValidationSvc validate.PodValidator, policyStore *validate.PolicyStore, globalPullSecret *validate.GlobalPullSecret, timeout time.Duration, logger *zap.SugaredLogger) *DefaultingWebHook {
 	return &DefaultingWebHook{
-		client:        client,
-		validationSvc: ValidationSvc,
-		baseLogger:    logger,
-		timeout:       timeout,
+		client:           client,
+		validationSvc:    ValidationSvc,
+		policyStore:      policyStore,
+		globalPullSecret: globalPullSecret,
+		baseLogger:       logger,
+		timeout:          timeout,
 	}
 }
 
@@ -100,6 +104,11 @@ func (w *DefaultingWebHook) handle(ctx context.Context, req admission.Request) a
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
+	ctx = w.withMatchedPolicy(ctx, pod)
+	if w.globalPullSecret != nil {
+		ctx = validate.ContextWithGlobalPullSecret(ctx, w.globalPullSecret)
+	}
+
 	result, err := w.validationSvc.ValidatePod(ctx, pod, ns)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
@@ -118,6 +127,18 @@ func (w *DefaultingWebHook) handle(ctx context.Context, req admission.Request) a
 	return admission.PatchResponseFromRaw(req.Object.Raw, fBytes)
 }
 
+// withMatchedPolicy attaches a policy lookup scoped to the pod's namespace,
+// so every container image validated against ctx -- Containers,
+// InitContainers, and EphemeralContainers alike -- is matched against its
+// own ImagePolicy instead of a single policy picked from one container up
+// front.
+func (w *DefaultingWebHook) withMatchedPolicy(ctx context.Context, pod *corev1.Pod) context.Context {
+	if w.policyStore == nil {
+		return ctx
+	}
+	return validate.ContextWithPolicyLookup(ctx, w.policyStore, pod.Namespace)
+}
+
 func (w *DefaultingWebHook) InjectDecoder(decoder *admission.Decoder) error {
 	w.decoder = decoder
 	return nil
@@ -151,4 +172,4 @@ func LabelForValidationResult(result validate.ValidationResult) string {
 	default:
 		return pkg.ValidationStatusPending
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,387 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"strings"
+
+	cliType "github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/kyma-project/warden/api/v1alpha1"
+	"github.com/kyma-project/warden/internal/helpers"
+	"github.com/kyma-project/warden/pkg"
+	"github.com/pkg/errors"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/fulcioroots"
+	ociremote "github.com/sigstore/cosign/v2/pkg/oci/remote"
+	rekorclient "github.com/sigstore/rekor/pkg/client"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+)
+
+// defaultRekorURL is used for keyless verification when neither the global
+// CosignConfig nor the matched policy's authority overrides it.
+const defaultRekorURL = "https://rekor.sigstore.dev"
+
+// CosignIdentity restricts keyless verification to signatures whose
+// certificate was issued for a matching subject by a matching issuer. Both
+// fields are regular expressions, mirroring sigstore's ClusterImagePolicy.
+type CosignIdentity struct {
+	Issuer  string
+	Subject string
+}
+
+// CosignConfig configures the cosign backend of ImageValidatorService. Set
+// either PublicKeys (classic verification) or Identities (keyless
+// verification); the two are mutually exclusive.
+type CosignConfig struct {
+	// PublicKeys are PEM-encoded ECDSA/ED25519 public keys. An image is
+	// accepted if at least one signature validates against any of them.
+	PublicKeys []string
+	// Identities, when non-empty, switches to keyless verification and
+	// restricts accepted signatures to the given issuer/subject pairs.
+	Identities []CosignIdentity
+	// RekorURL and FulcioURL override the default sigstore transparency log
+	// and CA used for keyless verification.
+	RekorURL  string
+	FulcioURL string
+}
+
+type cosignService struct {
+	ServiceConfig
+}
+
+// NewCosignValidator builds an ImageValidatorService backed by cosign/OCI
+// signature verification instead of Notary v1.
+func NewCosignValidator(sc *ServiceConfig) ImageValidatorService {
+	return &cosignService{
+		ServiceConfig: ServiceConfig{
+			CosignConfig:      sc.CosignConfig,
+			AllowedRegistries: sc.AllowedRegistries,
+		},
+	}
+}
+
+func (s *cosignService) Validate(ctx context.Context, image string, imagePullCredentials map[string]cliType.AuthConfig) error {
+	logger := helpers.LoggerFromCtx(ctx).With("image", image)
+	ctx = helpers.LoggerToContext(ctx, logger)
+
+	if allowed := s.isImageAllowed(image); allowed {
+		logger.Info("image validation skipped, because it's allowed")
+		return nil
+	}
+
+	imagePullCredentials = mergeGlobalPullSecret(ctx, imagePullCredentials)
+
+	// strict validation requires image name to contain domain and a tag, and/or sha256
+	ref, err := name.ParseReference(image, name.StrictValidation)
+	if err != nil {
+		return pkg.NewValidationFailedErr(errors.Wrap(err, "image name could not be parsed"))
+	}
+
+	policy, _ := PolicyFromContext(ctx, image)
+	signatureRef := RewriteForSignatures(ref, policy)
+	credentials := signatureCredentials(imagePullCredentials, policy)
+
+	if handled, err := verifyWithPolicyAuthorities(ctx, signatureRef, credentials, policy); handled {
+		return err
+	}
+
+	candidates, err := s.checkOptsCandidates(ctx, credentials)
+	if err != nil {
+		return err
+	}
+
+	return downgradeForMode(ctx, verifyAnyCheckOpts(ctx, signatureRef, candidates), policy)
+}
+
+// verifyWithPolicyAuthorities performs cosign verification against the
+// matched policy's own authorities instead of the global
+// ServiceConfig.CosignConfig/NotaryConfig, so different namespaces/images
+// can require different keys or issuers. handled is true whenever policy
+// carried authorities -- they then fully decide the outcome (downgraded per
+// policy.Mode), and callers should return err as-is without falling back to
+// their own backend logic.
+func verifyWithPolicyAuthorities(ctx context.Context, ref name.Reference, credentials map[string]cliType.AuthConfig, policy *CompiledPolicy) (handled bool, err error) {
+	if policy == nil || len(policy.Authorities) == 0 {
+		return false, nil
+	}
+
+	registryOpts := registryClientOpts(credentials)
+
+	var lastErr error
+	for _, authority := range policy.Authorities {
+		checkOpts, buildErr := checkOptsForAuthority(ctx, registryOpts, authority)
+		if buildErr != nil {
+			lastErr = buildErr
+			continue
+		}
+
+		closeLog := helpers.LogStartTime(ctx, "request to cosign (policy authority)")
+		// see verifyAnyCheckOpts: success is err == nil with signatures
+		// returned, not the bundle-verified flag.
+		sigs, _, verifyErr := cosign.VerifyImageSignatures(ctx, ref, checkOpts)
+		closeLog()
+		if verifyErr == nil && len(sigs) > 0 {
+			return true, nil
+		}
+		if verifyErr != nil {
+			lastErr = parseCosignErr(verifyErr)
+		} else {
+			lastErr = pkg.NewValidationFailedErr(errors.New("no valid cosign signature found for image"))
+		}
+	}
+	return true, downgradeForMode(ctx, lastErr, policy)
+}
+
+// checkOptsForAuthority builds the CheckOpts a single CompiledAuthority
+// verifies against: keyless when it carries identities, classic key-based
+// verification otherwise.
+func checkOptsForAuthority(ctx context.Context, registryOpts []ociremote.Option, authority CompiledAuthority) (*cosign.CheckOpts, error) {
+	if len(authority.Identities) > 0 {
+		return keylessCheckOpts(ctx, registryOpts, identitiesFromAuthority(authority.Identities), authority.RekorURL, "")
+	}
+
+	verifiers, err := loadPublicKeyVerifiers([]string{authority.PublicKeyPEM})
+	if err != nil {
+		return nil, pkg.NewUnknownResultErr(err)
+	}
+	// Key-based verification has no Rekor bundle to check; without IgnoreTlog,
+	// cosign tries online tlog verification against a nil RekorClient and
+	// always errors.
+	return &cosign.CheckOpts{RegistryClientOpts: registryOpts, SigVerifier: verifiers[0], IgnoreTlog: true}, nil
+}
+
+func identitiesFromAuthority(ids []v1alpha1.Identity) []CosignIdentity {
+	converted := make([]CosignIdentity, 0, len(ids))
+	for _, id := range ids {
+		converted = append(converted, CosignIdentity{Issuer: id.Issuer, Subject: id.Subject})
+	}
+	return converted
+}
+
+// checkOptsCandidates builds one cosign.CheckOpts per acceptable trust
+// source from the global CosignConfig: one per configured public key (an
+// image is accepted if any one of them verifies it), or a single keyless
+// CheckOpts carrying every configured identity (cosign itself accepts a
+// signature matching any one of CheckOpts.Identities).
+func (s *cosignService) checkOptsCandidates(ctx context.Context, credentials map[string]cliType.AuthConfig) ([]*cosign.CheckOpts, error) {
+	registryOpts := registryClientOpts(credentials)
+
+	if len(s.CosignConfig.Identities) > 0 {
+		opts, err := keylessCheckOpts(ctx, registryOpts, s.CosignConfig.Identities, s.CosignConfig.RekorURL, s.CosignConfig.FulcioURL)
+		if err != nil {
+			return nil, err
+		}
+		return []*cosign.CheckOpts{opts}, nil
+	}
+
+	if len(s.CosignConfig.PublicKeys) == 0 {
+		return nil, pkg.NewValidationFailedErr(errors.New("cosign backend requires either public keys or keyless identities"))
+	}
+
+	verifiers, err := loadPublicKeyVerifiers(s.CosignConfig.PublicKeys)
+	if err != nil {
+		return nil, pkg.NewUnknownResultErr(err)
+	}
+	candidates := make([]*cosign.CheckOpts, 0, len(verifiers))
+	for _, verifier := range verifiers {
+		candidates = append(candidates, &cosign.CheckOpts{
+			RegistryClientOpts: registryOpts,
+			SigVerifier:        verifier,
+			// Key-based verification has no Rekor bundle to check; without
+			// IgnoreTlog, cosign tries online tlog verification against a
+			// nil RekorClient and always errors.
+			IgnoreTlog: true,
+		})
+	}
+	return candidates, nil
+}
+
+// verifyAnyCheckOpts accepts ref as soon as one candidate verifies it,
+// mirroring "an image is accepted if at least one signature validates
+// against any of" the configured keys/identities.
+//
+// cosign.VerifyImageSignatures signals an invalid signature via a non-nil
+// err; its second return value only reports whether a Rekor bundle was
+// verified, not whether the signature itself is valid, so success is
+// "err == nil and at least one signature came back", not that flag.
+func verifyAnyCheckOpts(ctx context.Context, ref name.Reference, candidates []*cosign.CheckOpts) error {
+	var lastErr error
+	for _, checkOpts := range candidates {
+		closeLog := helpers.LogStartTime(ctx, "request to cosign")
+		sigs, _, err := cosign.VerifyImageSignatures(ctx, ref, checkOpts)
+		closeLog()
+		if err == nil && len(sigs) > 0 {
+			return nil
+		}
+		if err != nil {
+			lastErr = parseCosignErr(err)
+		} else {
+			lastErr = pkg.NewValidationFailedErr(errors.New("no valid cosign signature found for image"))
+		}
+	}
+	return lastErr
+}
+
+// keylessCheckOpts builds the CheckOpts for sigstore keyless verification:
+// a Rekor client for the transparency log, the Fulcio roots the leaf
+// certificate chains to, and the issuer/subject pairs a signing certificate
+// must match one of.
+func keylessCheckOpts(ctx context.Context, registryOpts []ociremote.Option, identities []CosignIdentity, rekorURL, fulcioURL string) (*cosign.CheckOpts, error) {
+	if rekorURL == "" {
+		rekorURL = defaultRekorURL
+	}
+
+	rc, err := rekorclient.GetRekorClient(rekorURL)
+	if err != nil {
+		return nil, pkg.NewUnknownResultErr(errors.Wrap(err, "create rekor client"))
+	}
+
+	rootCerts, err := fulcioRootCertPool(ctx, fulcioURL)
+	if err != nil {
+		return nil, pkg.NewUnknownResultErr(errors.Wrap(err, "load fulcio root certificates"))
+	}
+
+	opts := &cosign.CheckOpts{
+		RegistryClientOpts: registryOpts,
+		RekorClient:        rc,
+		RootCerts:          rootCerts,
+		Identities:         make([]cosign.Identity, 0, len(identities)),
+	}
+	for _, id := range identities {
+		opts.Identities = append(opts.Identities, cosign.Identity{
+			IssuerRegExp:  id.Issuer,
+			SubjectRegExp: id.Subject,
+		})
+	}
+	return opts, nil
+}
+
+// fulcioRootCertPool returns the Fulcio CA root certificates a keyless
+// signing certificate must chain to. An empty fulcioURL uses the public
+// sigstore.dev roots embedded via the TUF client; a custom fulcioURL is
+// queried directly at its documented root-certificate endpoint.
+func fulcioRootCertPool(ctx context.Context, fulcioURL string) (*x509.CertPool, error) {
+	if fulcioURL == "" {
+		return fulcioroots.Get()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(fulcioURL, "/")+"/api/v1/rootCert", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "build fulcio root certificate request")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch fulcio root certificate")
+	}
+	defer resp.Body.Close()
+
+	pemBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "read fulcio root certificate")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		return nil, errors.Errorf("no certificates found in fulcio root response from %s", fulcioURL)
+	}
+	return pool, nil
+}
+
+// loadPublicKeyVerifiers parses each PEM-encoded public key and returns a
+// signature.Verifier for it. Keys are verified individually -- cosign has no
+// "verify against any of N keys" API -- callers try each in turn.
+func loadPublicKeyVerifiers(pemKeys []string) ([]signature.Verifier, error) {
+	verifiers := make([]signature.Verifier, 0, len(pemKeys))
+	for _, pemKey := range pemKeys {
+		pub, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(pemKey))
+		if err != nil {
+			return nil, errors.Wrap(err, "parse public key")
+		}
+		verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+		if err != nil {
+			return nil, errors.Wrap(err, "load public key verifier")
+		}
+		verifiers = append(verifiers, verifier)
+	}
+	return verifiers, nil
+}
+
+func registryClientOpts(credentials map[string]cliType.AuthConfig) []ociremote.Option {
+	keychain := credentialKeychain{credentials: credentials}
+	return []ociremote.Option{ociremote.WithRemoteOptions(remote.WithAuthFromKeychain(keychain))}
+}
+
+// signatureCredentials overlays policy's SignaturePullSecrets-derived
+// credentials on top of imagePullCredentials, for use only when retrieving
+// signatures/trust data. The image itself is never pulled with these.
+func signatureCredentials(imagePullCredentials map[string]cliType.AuthConfig, policy *CompiledPolicy) map[string]cliType.AuthConfig {
+	if policy == nil || len(policy.SignatureCredentials) == 0 {
+		return imagePullCredentials
+	}
+
+	merged := make(map[string]cliType.AuthConfig, len(imagePullCredentials)+len(policy.SignatureCredentials))
+	for registry, auth := range imagePullCredentials {
+		merged[registry] = auth
+	}
+	for registry, auth := range policy.SignatureCredentials {
+		merged[registry] = auth
+	}
+	return merged
+}
+
+// credentialKeychain adapts the imagePullCredentials map the notary backend
+// already consumes into an authn.Keychain, so both backends share the same
+// credential plumbing.
+type credentialKeychain struct {
+	credentials map[string]cliType.AuthConfig
+}
+
+func (k credentialKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	credentials, ok := k.credentials[target.RegistryStr()]
+	if !ok {
+		return authn.Anonymous, nil
+	}
+	return parseCredentials(credentials)
+}
+
+func parseCosignErr(err error) error {
+	return pkg.NewValidationFailedErr(err)
+}
+
+// compositeService requires every wrapped ImageValidatorService to pass,
+// used for ServiceConfig.Backend == BackendBoth.
+type compositeService struct {
+	validators []ImageValidatorService
+}
+
+func (s *compositeService) Validate(ctx context.Context, image string, imagePullCredentials map[string]cliType.AuthConfig) error {
+	for _, validator := range s.validators {
+		if err := validator.Validate(ctx, image, imagePullCredentials); err != nil {
+			return err
+		}
+	}
+	return nil
+}
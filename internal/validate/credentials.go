@@ -0,0 +1,161 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/docker/cli/cli/config/configfile"
+	cliType "github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/pkg/errors"
+)
+
+// CredentialProvider resolves an authn.Authenticator for a registry beyond
+// the static username/password/token/auth forms parseCredentials handles,
+// so clusters that keep their registry credentials behind a docker
+// credential helper (ECR, GCR, ACR, ...) or in a mounted auth.json can still
+// be validated against. A provider is built fresh for every admission
+// request, so its cache never outlives the request it resolves auth for.
+type CredentialProvider struct {
+	authJSON *configfile.ConfigFile
+
+	mu    sync.Mutex
+	cache map[string]authn.Authenticator
+}
+
+// NewCredentialProvider loads authJSONPath, if set, as a docker config.json
+// and returns a CredentialProvider backed by it. An empty path is valid and
+// yields a provider with nothing to fall back to.
+func NewCredentialProvider(authJSONPath string) (*CredentialProvider, error) {
+	p := &CredentialProvider{cache: map[string]authn.Authenticator{}}
+	if authJSONPath == "" {
+		return p, nil
+	}
+
+	f, err := os.Open(authJSONPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "open auth.json")
+	}
+	defer f.Close()
+
+	cfg := configfile.New(authJSONPath)
+	if err := cfg.LoadFromReader(f); err != nil {
+		return nil, errors.Wrap(err, "parse auth.json")
+	}
+	p.authJSON = cfg
+	return p, nil
+}
+
+// Resolve returns an authn.Authenticator for registry. credentials/haveCredentials
+// carry whatever was already parsed from the pod's own imagePullSecrets; when
+// present they take precedence, mirroring the merge order used for the
+// cluster-wide global pull secret. Results are cached per registry.
+func (p *CredentialProvider) Resolve(registry string, credentials cliType.AuthConfig, haveCredentials bool) (authn.Authenticator, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cached, ok := p.cache[registry]; ok {
+		return cached, nil
+	}
+
+	auth, err := p.resolve(registry, credentials, haveCredentials)
+	if err != nil {
+		return nil, err
+	}
+	p.cache[registry] = auth
+	return auth, nil
+}
+
+func (p *CredentialProvider) resolve(registry string, credentials cliType.AuthConfig, haveCredentials bool) (authn.Authenticator, error) {
+	if haveCredentials {
+		return parseCredentials(credentials)
+	}
+
+	if p.authJSON == nil {
+		return nil, nil
+	}
+
+	if helper := p.credentialHelperFor(registry); helper != "" {
+		return resolveCredentialHelper(helper, registry)
+	}
+
+	if auth, ok := p.authJSON.AuthConfigs[registry]; ok {
+		return parseCredentials(auth)
+	}
+
+	return nil, nil
+}
+
+// credentialHelperFor returns the docker-credential-<helper> binary suffix
+// configured for registry, preferring a registry-specific credHelpers entry
+// over the config-wide credsStore.
+func (p *CredentialProvider) credentialHelperFor(registry string) string {
+	if helper, ok := p.authJSON.CredentialHelpers[registry]; ok {
+		return helper
+	}
+	return p.authJSON.CredentialsStore
+}
+
+// credentialHelperOutput is the JSON a docker credential helper prints to
+// stdout in response to a "get" request, per the credential-helper protocol.
+type credentialHelperOutput struct {
+	ServerURL string
+	Username  string
+	Secret    string
+}
+
+// credentialsNotFoundMessage is what docker-credential-helpers implementations
+// print (to stdout and/or stderr) when asked for a registry they hold no
+// credentials for; it's the de-facto "miss" sentinel of the helper protocol,
+// not a malfunction, and every configured registry (including a config-wide
+// credsStore applied to registries it was never meant to serve) will hit it
+// routinely.
+const credentialsNotFoundMessage = "credentials not found in native keychain"
+
+// resolveCredentialHelper shells out to docker-credential-<helper>, passing
+// registry on stdin and parsing the {ServerURL,Username,Secret} JSON it
+// writes to stdout. A helper reporting it has no credentials for registry is
+// not an error: it's treated as a miss so callers fall back to anonymous
+// access instead of failing validation of an unrelated/public image.
+func resolveCredentialHelper(helper, registry string) (authn.Authenticator, error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(registry)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if strings.Contains(stdout.String(), credentialsNotFoundMessage) ||
+			strings.Contains(stderr.String(), credentialsNotFoundMessage) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "run docker-credential-%s: %s", helper, strings.TrimSpace(stderr.String()))
+	}
+
+	var out credentialHelperOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, errors.Wrapf(err, "parse docker-credential-%s output", helper)
+	}
+
+	return &authn.Basic{Username: out.Username, Password: out.Secret}, nil
+}
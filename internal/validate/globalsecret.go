@@ -0,0 +1,128 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	cliType "github.com/docker/cli/cli/config/types"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// GlobalPullSecret holds the cluster-wide docker credentials configured via
+// the manager's --global-pull-secret flag, refreshed whenever the backing
+// Secret changes. It is merged into a pod's own imagePullSecrets with the
+// pod's credentials taking precedence.
+type GlobalPullSecret struct {
+	mu          sync.RWMutex
+	credentials map[string]cliType.AuthConfig
+}
+
+// NewGlobalPullSecret returns a GlobalPullSecret with no credentials; Update
+// populates it once the watching controller observes the Secret.
+func NewGlobalPullSecret() *GlobalPullSecret {
+	return &GlobalPullSecret{credentials: map[string]cliType.AuthConfig{}}
+}
+
+// Update replaces the held credentials with those parsed from secret's
+// corev1.DockerConfigJsonKey entry. Passing a nil/empty secret clears them,
+// which is how a deleted global pull secret is handled.
+func (g *GlobalPullSecret) Update(secret *corev1.Secret) error {
+	var raw []byte
+	if secret != nil {
+		raw = secret.Data[corev1.DockerConfigJsonKey]
+	}
+
+	credentials, err := ParseDockerConfigJSON(raw)
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.credentials = credentials
+	return nil
+}
+
+// Merge returns a new credentials map containing the global pull secret's
+// entries overlaid with podCredentials, so a pod's own imagePullSecrets
+// always take precedence over the cluster-wide default.
+func (g *GlobalPullSecret) Merge(podCredentials map[string]cliType.AuthConfig) map[string]cliType.AuthConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	merged := make(map[string]cliType.AuthConfig, len(g.credentials)+len(podCredentials))
+	for registry, auth := range g.credentials {
+		merged[registry] = auth
+	}
+	for registry, auth := range podCredentials {
+		merged[registry] = auth
+	}
+	return merged
+}
+
+// ParseDockerConfigJSON parses a kubernetes.io/dockerconfigjson secret
+// payload into a map keyed by registry hostname. A nil/empty payload parses
+// to an empty, non-nil map.
+func ParseDockerConfigJSON(data []byte) (map[string]cliType.AuthConfig, error) {
+	if len(data) == 0 {
+		return map[string]cliType.AuthConfig{}, nil
+	}
+
+	var parsed struct {
+		Auths map[string]cliType.AuthConfig `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, errors.Wrap(err, "parse dockerconfigjson")
+	}
+	if parsed.Auths == nil {
+		parsed.Auths = map[string]cliType.AuthConfig{}
+	}
+	return parsed.Auths, nil
+}
+
+// ParseGlobalPullSecretRef splits a --global-pull-secret=<namespace>/<name>
+// flag value into a NamespacedName.
+func ParseGlobalPullSecretRef(ref string) (types.NamespacedName, error) {
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found || namespace == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid --global-pull-secret value %q, expected <namespace>/<name>", ref)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
+
+type globalPullSecretContextKey struct{}
+
+// ContextWithGlobalPullSecret attaches the cluster-wide pull secret so that
+// ImageValidatorService implementations deeper in the call stack can merge
+// it into the per-pod imagePullCredentials they receive.
+func ContextWithGlobalPullSecret(ctx context.Context, secret *GlobalPullSecret) context.Context {
+	return context.WithValue(ctx, globalPullSecretContextKey{}, secret)
+}
+
+// GlobalPullSecretFromContext returns the secret attached by
+// ContextWithGlobalPullSecret, if any.
+func GlobalPullSecretFromContext(ctx context.Context) (*GlobalPullSecret, bool) {
+	secret, ok := ctx.Value(globalPullSecretContextKey{}).(*GlobalPullSecret)
+	return secret, ok
+}
@@ -0,0 +1,119 @@
+package validate
+
+import (
+	"encoding/json"
+	"testing"
+
+	cliType "github.com/docker/cli/cli/config/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func dockerConfigJSONSecret(t *testing.T, auths map[string]cliType.AuthConfig) *corev1.Secret {
+	t.Helper()
+	raw, err := json.Marshal(struct {
+		Auths map[string]cliType.AuthConfig `json:"auths"`
+	}{Auths: auths})
+	if err != nil {
+		t.Fatalf("marshal dockerconfigjson: %v", err)
+	}
+	return &corev1.Secret{Data: map[string][]byte{corev1.DockerConfigJsonKey: raw}}
+}
+
+func TestGlobalPullSecret_MergePrecedence(t *testing.T) {
+	global := NewGlobalPullSecret()
+	err := global.Update(dockerConfigJSONSecret(t, map[string]cliType.AuthConfig{
+		"global.registry.io": {Username: "global-user", Password: "global-pass"},
+		"shared.registry.io": {Username: "global-user", Password: "global-pass"},
+	}))
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	podCredentials := map[string]cliType.AuthConfig{
+		"shared.registry.io": {Username: "pod-user", Password: "pod-pass"},
+		"pod.registry.io":    {Username: "pod-user", Password: "pod-pass"},
+	}
+
+	merged := global.Merge(podCredentials)
+
+	if got := merged["shared.registry.io"]; got.Username != "pod-user" {
+		t.Errorf("pod credentials should take precedence for a shared registry, got %+v", got)
+	}
+	if got := merged["global.registry.io"]; got.Username != "global-user" {
+		t.Errorf("expected global-only registry to be present, got %+v", got)
+	}
+	if got := merged["pod.registry.io"]; got.Username != "pod-user" {
+		t.Errorf("expected pod-only registry to be present, got %+v", got)
+	}
+}
+
+func TestGlobalPullSecret_HotReload(t *testing.T) {
+	global := NewGlobalPullSecret()
+	if err := global.Update(dockerConfigJSONSecret(t, map[string]cliType.AuthConfig{
+		"registry.io": {Username: "old-user", Password: "old-pass"},
+	})); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if got := global.Merge(nil)["registry.io"]; got.Username != "old-user" {
+		t.Fatalf("expected initial credentials, got %+v", got)
+	}
+
+	if err := global.Update(dockerConfigJSONSecret(t, map[string]cliType.AuthConfig{
+		"registry.io": {Username: "new-user", Password: "new-pass"},
+	})); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if got := global.Merge(nil)["registry.io"]; got.Username != "new-user" {
+		t.Errorf("expected reloaded credentials after Update, got %+v", got)
+	}
+}
+
+func TestGlobalPullSecret_UpdateWithNilSecretClearsCredentials(t *testing.T) {
+	global := NewGlobalPullSecret()
+	if err := global.Update(dockerConfigJSONSecret(t, map[string]cliType.AuthConfig{
+		"registry.io": {Username: "user", Password: "pass"},
+	})); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if err := global.Update(nil); err != nil {
+		t.Fatalf("Update(nil): %v", err)
+	}
+
+	if merged := global.Merge(nil); len(merged) != 0 {
+		t.Errorf("expected no credentials after clearing, got %+v", merged)
+	}
+}
+
+func TestParseGlobalPullSecretRef(t *testing.T) {
+	cases := []struct {
+		ref       string
+		wantNs    string
+		wantName  string
+		expectErr bool
+	}{
+		{ref: "kyma-system/warden-global-pull-secret", wantNs: "kyma-system", wantName: "warden-global-pull-secret"},
+		{ref: "missing-slash", expectErr: true},
+		{ref: "/no-namespace", expectErr: true},
+		{ref: "no-name/", expectErr: true},
+	}
+
+	for _, c := range cases {
+		got, err := ParseGlobalPullSecretRef(c.ref)
+		if c.expectErr {
+			if err == nil {
+				t.Errorf("ParseGlobalPullSecretRef(%q): expected error, got none", c.ref)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseGlobalPullSecretRef(%q): unexpected error: %v", c.ref, err)
+			continue
+		}
+		if got.Namespace != c.wantNs || got.Name != c.wantName {
+			t.Errorf("ParseGlobalPullSecretRef(%q) = %+v, want namespace=%s name=%s", c.ref, got, c.wantNs, c.wantName)
+		}
+	}
+}
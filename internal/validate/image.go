@@ -37,9 +37,25 @@ type ImageValidatorService interface {
 	Validate(ctx context.Context, image string, imagePullCredentials map[string]cliType.AuthConfig) error
 }
 
+// Backend selects which signature verification implementation(s)
+// NewImageValidator wires up.
+const (
+	BackendNotary = "notary"
+	BackendCosign = "cosign"
+	BackendBoth   = "both"
+)
+
 type ServiceConfig struct {
 	NotaryConfig      NotaryConfig
+	CosignConfig      CosignConfig
 	AllowedRegistries []string
+	// Backend picks the ImageValidatorService implementation. Empty defaults
+	// to BackendNotary for backward compatibility.
+	Backend string
+	// AuthJSONPath, if set, points to a mounted docker config.json consulted
+	// when a registry has no matching entry in imagePullCredentials. It is
+	// also where credsStore/credHelpers are read from.
+	AuthJSONPath string
 }
 
 type notaryService struct {
@@ -47,11 +63,28 @@ type notaryService struct {
 	RepoFactory RepoFactory
 }
 
+// NewImageValidator builds the ImageValidatorService selected by
+// sc.Backend. BackendBoth requires both the notary and cosign checks to
+// pass before an image is considered valid.
 func NewImageValidator(sc *ServiceConfig, notaryClientFactory RepoFactory) ImageValidatorService {
+	notary := newNotaryValidator(sc, notaryClientFactory)
+
+	switch sc.Backend {
+	case BackendCosign:
+		return NewCosignValidator(sc)
+	case BackendBoth:
+		return &compositeService{validators: []ImageValidatorService{notary, NewCosignValidator(sc)}}
+	default:
+		return notary
+	}
+}
+
+func newNotaryValidator(sc *ServiceConfig, notaryClientFactory RepoFactory) *notaryService {
 	return &notaryService{
 		ServiceConfig: ServiceConfig{
 			NotaryConfig:      sc.NotaryConfig,
 			AllowedRegistries: sc.AllowedRegistries,
+			AuthJSONPath:      sc.AuthJSONPath,
 		},
 		RepoFactory: notaryClientFactory,
 	}
@@ -66,18 +99,33 @@ func (s *notaryService) Validate(ctx context.Context, image string, imagePullCre
 		return nil
 	}
 
+	imagePullCredentials = mergeGlobalPullSecret(ctx, imagePullCredentials)
+
 	// strict validation requires image name to contain domain and a tag, and/or sha256
 	ref, err := name.ParseReference(image, name.StrictValidation)
 	if err != nil {
 		return pkg.NewValidationFailedErr(errors.Wrap(err, "image name could not be parsed"))
 	}
 
-	expectedShaBytes, err := s.loggedGetNotaryImageDigestHash(ctx, ref)
+	policy, _ := PolicyFromContext(ctx, image)
+	signatureRef := RewriteForSignatures(ref, policy)
+	if handled, err := verifyWithPolicyAuthorities(ctx, signatureRef, signatureCredentials(imagePullCredentials, policy), policy); handled {
+		return err
+	}
+
+	expectedShaBytes, err := s.loggedGetNotaryImageDigestHash(ctx, image, ref)
 	if err != nil {
 		return err
 	}
 
-	shaImageBytes, shaManifestBytes, err := s.loggedGetRepositoryDigestHash(ctx, ref, imagePullCredentials)
+	// a fresh provider per Validate call, so cached authenticators never
+	// outlive the admission request they were resolved for
+	credentialProvider, err := NewCredentialProvider(s.AuthJSONPath)
+	if err != nil {
+		return pkg.NewUnknownResultErr(errors.Wrap(err, "load auth.json"))
+	}
+
+	shaImageBytes, shaManifestBytes, err := s.loggedGetRepositoryDigestHash(ctx, ref, imagePullCredentials, credentialProvider)
 	if err != nil {
 		return err
 	}
@@ -91,11 +139,24 @@ func (s *notaryService) Validate(ctx context.Context, image string, imagePullCre
 		return nil
 	}
 
-	return pkg.NewValidationFailedErr(errors.New("unexpected image hash value"))
+	return downgradeForMode(ctx, pkg.NewValidationFailedErr(errors.New("unexpected image hash value")), policy)
+}
+
+// mergeGlobalPullSecret overlays the cluster-wide pull secret attached to
+// ctx (if any) under podCredentials, so a pod's own imagePullSecrets always
+// take precedence.
+func mergeGlobalPullSecret(ctx context.Context, podCredentials map[string]cliType.AuthConfig) map[string]cliType.AuthConfig {
+	global, ok := GlobalPullSecretFromContext(ctx)
+	if !ok {
+		return podCredentials
+	}
+	return global.Merge(podCredentials)
 }
 
-func (s *notaryService) isImageAllowed(imgRepo string) bool {
-	for _, allowed := range s.AllowedRegistries {
+// isImageAllowed is shared by every ImageValidatorService implementation,
+// since the allow-list is backend-agnostic.
+func (sc ServiceConfig) isImageAllowed(imgRepo string) bool {
+	for _, allowed := range sc.AllowedRegistries {
 		// repository is in allowed list
 		if strings.HasPrefix(imgRepo, allowed) {
 			return true
@@ -104,39 +165,37 @@ func (s *notaryService) isImageAllowed(imgRepo string) bool {
 	return false
 }
 
-func (s *notaryService) loggedGetRepositoryDigestHash(ctx context.Context, ref name.Reference, imagePullCredentials map[string]cliType.AuthConfig) ([]byte, []byte, error) {
+func (s *notaryService) loggedGetRepositoryDigestHash(ctx context.Context, ref name.Reference, imagePullCredentials map[string]cliType.AuthConfig, credentialProvider *CredentialProvider) ([]byte, []byte, error) {
 	const message = "request to image registry"
 	closeLog := helpers.LogStartTime(ctx, message)
 	defer closeLog()
-	return s.getRepositoryDigestHash(ref, imagePullCredentials)
+	return s.getRepositoryDigestHash(ref, imagePullCredentials, credentialProvider)
 }
 
-func (s *notaryService) getRepositoryDigestHash(ref name.Reference, imagePullCredentials map[string]cliType.AuthConfig) ([]byte, []byte, error) {
+func (s *notaryService) getRepositoryDigestHash(ref name.Reference, imagePullCredentials map[string]cliType.AuthConfig, credentialProvider *CredentialProvider) ([]byte, []byte, error) {
 	remoteOptions := make([]remote.Option, 0)
 
-	credentials, credentialsOk := imagePullCredentials[ref.Context().RegistryStr()]
+	registry := ref.Context().RegistryStr()
+	credentials, credentialsOk := imagePullCredentials[registry]
 
 	//try to get image info without credentials, mimicking Kuberenetes behavior
 	descriptor, err := remote.Get(ref)
 	if err != nil {
-		if !credentialsOk {
+		// no fitting pod-supplied credentials; fall back to credential
+		// helpers/auth.json before giving up
+		authenticator, resolveErr := credentialProvider.Resolve(registry, credentials, credentialsOk)
+		if resolveErr != nil {
+			return nil, nil, resolveErr
+		}
+		if authenticator == nil {
 			// no fitting credentials, and no public access, return error
 			return nil, nil, pkg.NewUnknownResultErr(errors.Wrap(err, "get image descriptor anonymously"))
-		} else {
-			// to to authenticate to the registry
-
-			credentials, err := parseCredentials(credentials)
-			if err != nil {
-				return nil, nil, err
-			}
-
-			if credentials != nil {
-				remoteOptions = append(remoteOptions, remote.WithAuth(credentials))
-			}
-			descriptor, err = remote.Get(ref, remoteOptions...)
-			if err != nil {
-				return nil, nil, pkg.NewUnknownResultErr(errors.Wrap(err, "get image descriptor"))
-			}
+		}
+
+		remoteOptions = append(remoteOptions, remote.WithAuth(authenticator))
+		descriptor, err = remote.Get(ref, remoteOptions...)
+		if err != nil {
+			return nil, nil, pkg.NewUnknownResultErr(errors.Wrap(err, "get image descriptor"))
 		}
 	}
 
@@ -227,18 +286,21 @@ func getImageDigestHash(ref name.Reference, remoteOptions ...remote.Option) ([]b
 	return digestBytes, manifestBytes, nil
 }
 
-func (s *notaryService) loggedGetNotaryImageDigestHash(ctx context.Context, ref name.Reference) ([]byte, error) {
+func (s *notaryService) loggedGetNotaryImageDigestHash(ctx context.Context, image string, ref name.Reference) ([]byte, error) {
 	const message = "request to notary"
 	closeLog := helpers.LogStartTime(ctx, message)
 	defer closeLog()
-	result, err := s.getNotaryImageDigestHash(ctx, ref)
+	result, err := s.getNotaryImageDigestHash(ctx, image, ref)
 	return result, err
 }
 
-func (s *notaryService) getNotaryImageDigestHash(ctx context.Context, ref name.Reference) ([]byte, error) {
+func (s *notaryService) getNotaryImageDigestHash(ctx context.Context, image string, ref name.Reference) ([]byte, error) {
+	policy, _ := PolicyFromContext(ctx, image)
+	signatureRef := RewriteForSignatures(ref, policy)
+
 	const messageNewRepoClient = "request to notary (NewRepoClient)"
 	closeLog := helpers.LogStartTime(ctx, messageNewRepoClient)
-	c, err := s.RepoFactory.NewRepoClient(ref.Context().Name(), s.NotaryConfig)
+	c, err := s.RepoFactory.NewRepoClient(signatureRef.Context().Name(), s.NotaryConfig)
 	closeLog()
 	if err != nil {
 		return nil, pkg.NewUnknownResultErr(err)
@@ -246,7 +308,7 @@ func (s *notaryService) getNotaryImageDigestHash(ctx context.Context, ref name.R
 
 	const messageGetTargetByName = "request to notary (GetTargetByName)"
 	closeLog = helpers.LogStartTime(ctx, messageGetTargetByName)
-	target, err := c.GetTargetByName(ref.Identifier())
+	target, err := c.GetTargetByName(signatureRef.Identifier())
 	closeLog()
 	if err != nil {
 		return nil, parseNotaryErr(err)
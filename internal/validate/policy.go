@@ -0,0 +1,339 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validate
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	cliType "github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/kyma-project/warden/api/v1alpha1"
+	"github.com/kyma-project/warden/internal/helpers"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CompiledPolicy is the validated, ready-to-match form of an
+// v1alpha1.ImagePolicy, as published into a PolicyStore by
+// ImagePolicyReconciler.
+type CompiledPolicy struct {
+	Namespace string
+	Name      string
+	Globs     []string
+	// Authorities are resolved (any SecretRef already read) and override the
+	// global ServiceConfig's trust sources for images this policy matches.
+	Authorities []CompiledAuthority
+	Mode        v1alpha1.PolicyMode
+
+	// SignatureRegistry, if set, replaces the registry host used when
+	// building the reference that signatures/trust data are fetched from.
+	SignatureRegistry string
+	// SignatureCredentials are resolved from spec.signaturePullSecrets by
+	// the reconciler and are only merged into the credentials used to
+	// retrieve signatures/trust data, never to pull the image itself.
+	SignatureCredentials map[string]cliType.AuthConfig
+}
+
+// CompiledAuthority is the resolved, verification-ready form of a
+// v1alpha1.Authority: a Key's SecretRef, if any, has already been read into
+// PublicKeyPEM. Exactly one of PublicKeyPEM or Identities is set.
+type CompiledAuthority struct {
+	// PublicKeyPEM is set for classic (key-based) authorities.
+	PublicKeyPEM string
+	// Identities and RekorURL are set for keyless authorities.
+	Identities []v1alpha1.Identity
+	RekorURL   string
+}
+
+// SecretKeyResolver reads the "cosign.pub" (or equivalent) entry of the
+// Secret referenced by ref, scoped to the policy's own namespace.
+type SecretKeyResolver func(ref corev1.LocalObjectReference) (string, error)
+
+// ResolveAuthorities turns spec-level authorities -- which may reference a
+// Secret rather than carrying key data inline -- into their resolved,
+// verification-ready form.
+func ResolveAuthorities(authorities []v1alpha1.Authority, resolveKey SecretKeyResolver) ([]CompiledAuthority, error) {
+	resolved := make([]CompiledAuthority, 0, len(authorities))
+	for _, authority := range authorities {
+		switch {
+		case authority.Keyless != nil:
+			var rekorURL string
+			if authority.Keyless.Rekor != nil {
+				rekorURL = authority.Keyless.Rekor.URL
+			}
+			resolved = append(resolved, CompiledAuthority{
+				Identities: authority.Keyless.Identities,
+				RekorURL:   rekorURL,
+			})
+		case authority.Key != nil:
+			keyPEM := authority.Key.Data
+			if authority.Key.SecretRef != nil {
+				data, err := resolveKey(*authority.Key.SecretRef)
+				if err != nil {
+					return nil, errors.Wrapf(err, "resolve key secret %q", authority.Key.SecretRef.Name)
+				}
+				keyPEM = data
+			}
+			resolved = append(resolved, CompiledAuthority{PublicKeyPEM: keyPEM})
+		default:
+			return nil, errors.New("spec.authorities[] must set key or keyless")
+		}
+	}
+	return resolved, nil
+}
+
+// downgradeForMode lets policy.Mode decide what a failed verification means:
+// PolicyModeWarn logs and allows the image through, PolicyModeEnforce (the
+// default) passes err through unchanged.
+func downgradeForMode(ctx context.Context, err error, policy *CompiledPolicy) error {
+	if err == nil || policy == nil || policy.Mode != v1alpha1.PolicyModeWarn {
+		return err
+	}
+	helpers.LoggerFromCtx(ctx).Warnf("policy %s/%s is in warn mode, allowing image despite: %s", policy.Namespace, policy.Name, err)
+	return nil
+}
+
+// Matches reports whether image is covered by one of the policy's glob
+// patterns. Per ImageSelector.Glob's contract, matching ignores the image's
+// tag/digest.
+func (p *CompiledPolicy) Matches(image string) bool {
+	repo := repositoryOnly(image)
+	for _, glob := range p.Globs {
+		if ok, err := filepath.Match(glob, repo); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryOnly returns image with its tag/digest stripped, as the
+// "registry/repository" string filepath.Match'ed against Globs. Falls back
+// to image unchanged if it fails to parse as an image reference.
+//
+// Note: filepath.Match's "*" does not cross "/", so a glob like
+// "registry.internal/*" only ever matches single-segment repositories
+// directly under that registry; a multi-segment repository such as
+// "registry.internal/team/foo" needs its own glob (or "registry.internal/*/*").
+func repositoryOnly(image string) string {
+	ref, err := name.ParseReference(image, name.WeakValidation)
+	if err != nil {
+		return image
+	}
+	return ref.Context().Name()
+}
+
+// CompilePolicy validates an ImagePolicy's spec and returns its compiled
+// form. It is used by both the reconciler and the offline policy tester, so
+// CR validation only happens in one place.
+func CompilePolicy(key types.NamespacedName, spec v1alpha1.ImagePolicySpec) (*CompiledPolicy, error) {
+	if len(spec.Images) == 0 {
+		return nil, errors.New("spec.images must not be empty")
+	}
+	if len(spec.Authorities) == 0 {
+		return nil, errors.New("spec.authorities must not be empty")
+	}
+
+	globs := make([]string, 0, len(spec.Images))
+	for _, img := range spec.Images {
+		if img.Glob == "" {
+			return nil, errors.New("spec.images[].glob must not be empty")
+		}
+		if _, err := filepath.Match(img.Glob, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid glob %q", img.Glob)
+		}
+		globs = append(globs, img.Glob)
+	}
+
+	for _, authority := range spec.Authorities {
+		if authority.Key == nil && authority.Keyless == nil {
+			return nil, errors.New("spec.authorities[] must set key or keyless")
+		}
+	}
+
+	mode := spec.Mode
+	if mode == "" {
+		mode = v1alpha1.PolicyModeEnforce
+	}
+
+	var signatureRegistry string
+	if spec.SignatureSource != nil {
+		signatureRegistry = spec.SignatureSource.Registry
+	}
+
+	// Authorities is intentionally left unset here: resolving a Key's
+	// SecretRef needs a client, which CompilePolicy (shared with the offline
+	// tester) doesn't have. Callers must set it via ResolveAuthorities.
+	return &CompiledPolicy{
+		Namespace:         key.Namespace,
+		Name:              key.Name,
+		Globs:             globs,
+		Mode:              mode,
+		SignatureRegistry: signatureRegistry,
+	}, nil
+}
+
+// RewriteForSignatures returns the reference used to fetch signatures/trust
+// data for ref, honouring policy's SignatureRegistry if set. The image pull
+// reference (ref itself) is never modified; this only affects where the
+// ImageValidatorService backends look for trust data. Falls back to ref
+// unchanged if policy is nil, has no rewrite configured, or the rewritten
+// reference fails to parse.
+func RewriteForSignatures(ref name.Reference, policy *CompiledPolicy) name.Reference {
+	if policy == nil || policy.SignatureRegistry == "" {
+		return ref
+	}
+
+	separator := ":"
+	if _, isDigest := ref.(name.Digest); isDigest {
+		separator = "@"
+	}
+
+	rewritten := policy.SignatureRegistry + "/" + ref.Context().RepositoryStr() + separator + ref.Identifier()
+	signatureRef, err := name.ParseReference(rewritten, name.WeakValidation)
+	if err != nil {
+		return ref
+	}
+	return signatureRef
+}
+
+// PolicyStore is an in-memory, concurrency-safe snapshot of every
+// compiled ImagePolicy in the cluster, keyed by namespace. ImagePolicyReconciler
+// is the only writer; DefaultingWebHook and PodValidator are readers.
+type PolicyStore struct {
+	mu       sync.RWMutex
+	byPolicy map[types.NamespacedName]*CompiledPolicy
+	byNS     map[string][]*CompiledPolicy
+}
+
+// NewPolicyStore returns an empty PolicyStore.
+func NewPolicyStore() *PolicyStore {
+	return &PolicyStore{
+		byPolicy: map[types.NamespacedName]*CompiledPolicy{},
+		byNS:     map[string][]*CompiledPolicy{},
+	}
+}
+
+// Set publishes or replaces the compiled policy for key.
+func (s *PolicyStore) Set(key types.NamespacedName, policy *CompiledPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byPolicy[key] = policy
+	s.rebuildNSLocked()
+}
+
+// Delete removes the policy for key, if present.
+func (s *PolicyStore) Delete(key types.NamespacedName) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.byPolicy, key)
+	s.rebuildNSLocked()
+}
+
+func (s *PolicyStore) rebuildNSLocked() {
+	byNS := map[string][]*CompiledPolicy{}
+	for _, p := range s.byPolicy {
+		byNS[p.Namespace] = append(byNS[p.Namespace], p)
+	}
+	// s.byPolicy is a map, so the append order above is non-deterministic;
+	// sort each namespace's policies by name so Match's first-match-wins
+	// result stays stable across reconciles instead of depending on
+	// iteration order.
+	for ns := range byNS {
+		sort.Slice(byNS[ns], func(i, j int) bool {
+			return byNS[ns][i].Name < byNS[ns][j].Name
+		})
+	}
+	s.byNS = byNS
+}
+
+// Match returns the first policy in namespace (ordered by name) whose image
+// globs match image, if any.
+func (s *PolicyStore) Match(namespace, image string) (*CompiledPolicy, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, p := range s.byNS[namespace] {
+		if p.Matches(image) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+type policyContextKey struct{}
+
+// policyLookup resolves the ImagePolicy covering a single image. It is
+// attached to ctx once per pod (or tester run) and consulted per image, so a
+// pod whose containers span multiple policies/registries gets the right
+// policy for each container instead of one policy applied to all of them.
+type policyLookup interface {
+	match(image string) (*CompiledPolicy, bool)
+}
+
+// storeLookup matches against every policy of a fixed namespace, live from
+// the PolicyStore -- this is what DefaultingWebHook attaches, since it knows
+// the pod's namespace but not which container is being validated yet.
+type storeLookup struct {
+	store     *PolicyStore
+	namespace string
+}
+
+func (l storeLookup) match(image string) (*CompiledPolicy, bool) {
+	if l.store == nil {
+		return nil, false
+	}
+	return l.store.Match(l.namespace, image)
+}
+
+// staticLookup always returns the same, already-resolved policy, regardless
+// of image. Used by callers (e.g. the offline tester) that resolve the
+// policy for an image themselves and want to pin it for a single Validate call.
+type staticLookup struct {
+	policy *CompiledPolicy
+}
+
+func (l staticLookup) match(string) (*CompiledPolicy, bool) {
+	return l.policy, l.policy != nil
+}
+
+// ContextWithPolicyLookup attaches store, scoped to namespace, so every
+// image validated against ctx (e.g. every container of a pod) is matched
+// against its own image rather than a single policy picked in advance.
+func ContextWithPolicyLookup(ctx context.Context, store *PolicyStore, namespace string) context.Context {
+	return context.WithValue(ctx, policyContextKey{}, storeLookup{store: store, namespace: namespace})
+}
+
+// ContextWithPolicy attaches a single, already-matched policy for the image
+// currently being validated, so ImageValidatorService implementations
+// consulted deeper in the call stack can honour its authorities/mode instead
+// of the global ServiceConfig.
+func ContextWithPolicy(ctx context.Context, policy *CompiledPolicy) context.Context {
+	return context.WithValue(ctx, policyContextKey{}, staticLookup{policy: policy})
+}
+
+// PolicyFromContext returns the policy matching image, as attached by
+// ContextWithPolicyLookup or ContextWithPolicy, if any.
+func PolicyFromContext(ctx context.Context, image string) (*CompiledPolicy, bool) {
+	lookup, ok := ctx.Value(policyContextKey{}).(policyLookup)
+	if !ok {
+		return nil, false
+	}
+	return lookup.match(image)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/kyma-project/warden/api/v1alpha1"
+	"github.com/kyma-project/warden/controllers"
+	"github.com/kyma-project/warden/internal/admission"
+	"github.com/kyma-project/warden/internal/validate"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlzap "sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	scheme = runtime.NewScheme()
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+}
+
+func main() {
+	var metricsAddr string
+	var webhookPort int
+	var globalPullSecretRef string
+	var requestTimeout time.Duration
+
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.IntVar(&webhookPort, "webhook-port", 9443, "The port the admission webhook server binds to.")
+	flag.StringVar(&globalPullSecretRef, "global-pull-secret", "",
+		"Optional <namespace>/<name> of a cluster-wide dockerconfigjson Secret tried for every image, with per-pod imagePullSecrets taking precedence.")
+	flag.DurationVar(&requestTimeout, "validation-timeout", 30*time.Second, "Timeout for a single admission validation request.")
+	flag.Parse()
+
+	ctrl.SetLogger(ctrlzap.New(ctrlzap.UseDevMode(false)))
+
+	zapLogger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer zapLogger.Sync() //nolint:errcheck
+	logger := zapLogger.Sugar()
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr,
+		Port:                   webhookPort,
+		HealthProbeBindAddress: ":8081",
+	})
+	if err != nil {
+		logger.Errorw("unable to start manager", "error", err)
+		os.Exit(1)
+	}
+
+	policyStore := validate.NewPolicyStore()
+	globalPullSecret := validate.NewGlobalPullSecret()
+
+	if err = (&controllers.ImagePolicyReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		Store:  policyStore,
+	}).SetupWithManager(mgr); err != nil {
+		logger.Errorw("unable to create controller", "controller", "ImagePolicy", "error", err)
+		os.Exit(1)
+	}
+
+	if globalPullSecretRef != "" {
+		key, err := validate.ParseGlobalPullSecretRef(globalPullSecretRef)
+		if err != nil {
+			logger.Errorw("invalid --global-pull-secret", "error", err)
+			os.Exit(1)
+		}
+		if err = (&controllers.GlobalPullSecretReconciler{
+			Client: mgr.GetClient(),
+			Key:    key,
+			Target: globalPullSecret,
+		}).SetupWithManager(mgr); err != nil {
+			logger.Errorw("unable to create controller", "controller", "GlobalPullSecret", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// TODO(user): construct the ImageValidatorService/PodValidator used in
+	// production (notary/cosign client factories, ServiceConfig) and pass it
+	// as ValidationSvc below; left out here since that wiring predates this
+	// change and lives outside this trimmed checkout.
+	var podValidator validate.PodValidator
+
+	webhookServer := mgr.GetWebhookServer()
+	webhookServer.Register(admission.DefaultingPath, &webhook.Admission{
+		Handler: admission.NewDefaultingWebhook(mgr.GetClient(), podValidator, policyStore, globalPullSecret, requestTimeout, logger),
+	})
+
+	logger.Info("starting manager")
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		logger.Errorw("problem running manager", "error", err)
+		os.Exit(1)
+	}
+}